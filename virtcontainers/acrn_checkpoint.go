@@ -0,0 +1,21 @@
+// Copyright (c) 2020 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import "context"
+
+// ACRN has no VM migration/snapshot facility, so it cannot take part in
+// Sandbox.Checkpoint/Restore. save and load exist only to satisfy
+// checkpointableHypervisor type assertions elsewhere; callers should
+// expect ErrNotSupported and fall back to a non-checkpointing code path.
+
+func (a *Acrn) save(ctx context.Context, path string) error {
+	return ErrNotSupported
+}
+
+func (a *Acrn) load(ctx context.Context, path string) error {
+	return ErrNotSupported
+}
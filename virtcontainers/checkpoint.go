@@ -0,0 +1,338 @@
+// Copyright (c) 2020 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/kata-containers/runtime/virtcontainers/store"
+)
+
+const (
+	// configDumpFile holds the serialized container runtime metadata
+	// (bundle path, OCI spec, mounts, network attachments) captured at
+	// checkpoint time.
+	configDumpFile = "config.dump"
+
+	// specDumpFile is the suffix of the per-container
+	// <container-id>-spec.dump files holding the OCI runtime spec in
+	// effect when the checkpoint was taken.
+	specDumpFile = "spec.dump"
+
+	// memoryDumpFile holds the guest memory and device state image
+	// produced by the hypervisor (QEMU migrate "exec:...", CLH's
+	// snapshot RPC, ...).
+	memoryDumpFile = "memory.img"
+)
+
+// CheckpointOptions groups the knobs that influence how a sandbox is
+// quiesced and dumped to disk.
+type CheckpointOptions struct {
+	// Directory is the bundle directory the checkpoint is written to.
+	// It is created if it does not already exist.
+	Directory string
+
+	// LeaveRunning keeps the sandbox and its hypervisor process alive
+	// after the checkpoint has been taken, mirroring CRIU's
+	// --leave-running and podman's --leave-running.
+	LeaveRunning bool
+}
+
+// RestoreOptions groups the knobs that influence how a sandbox is
+// recreated from a checkpoint bundle.
+type RestoreOptions struct {
+	// Directory is the bundle directory a previous Checkpoint call
+	// wrote to.
+	Directory string
+}
+
+// checkpointableHypervisor is implemented by hypervisor backends that can
+// dump their guest memory and device state to, and reload it from, the
+// given path on disk (memoryDumpFile inside the checkpoint bundle
+// directory). QEMU and Cloud Hypervisor provide real
+// implementations; ACRN has no migration facility and returns
+// ErrNotSupported.
+type checkpointableHypervisor interface {
+	save(ctx context.Context, path string) error
+	load(ctx context.Context, path string) error
+}
+
+// sandboxDump is the content of configDumpFile: everything needed to
+// recreate a Sandbox's bookkeeping without re-deriving it from the
+// hypervisor image.
+type sandboxDump struct {
+	ID     string        `json:"id"`
+	Config SandboxConfig `json:"config"`
+
+	// NetworkEndpoints is the MAC address of every endpoint in the
+	// sandbox's network namespace at checkpoint time, in s.networkNS
+	// iteration order. These are discovered at boot onto the running
+	// sandbox rather than recorded in SandboxConfig, so they have to
+	// be captured here for Restore to verify network identity.
+	NetworkEndpoints []string `json:"network_endpoints"`
+
+	ContainerDump map[string]containerDump `json:"containers"`
+}
+
+// containerDump is the per-container slice of sandboxDump: bundle, spec
+// and mount/network state captured at checkpoint time.
+type containerDump struct {
+	Bundle  string  `json:"bundle"`
+	RootFs  string  `json:"rootfs"`
+	Mounts  []Mount `json:"mounts"`
+	NetNSID string  `json:"netns_id,omitempty"`
+}
+
+// Checkpoint quiesces every container running in the sandbox, drives the
+// hypervisor to dump guest memory and device state, and writes the
+// resulting bundle (memory image + config.dump + one
+// <container-id>-spec.dump per container) under
+// opts.Directory. It is the virtcontainers analogue of libpod's
+// `podman container checkpoint`.
+func (s *Sandbox) Checkpoint(ctx context.Context, opts CheckpointOptions) error {
+	span, ctx := s.trace(ctx, "Checkpoint")
+	defer span.End()
+
+	if opts.Directory == "" {
+		return fmt.Errorf("checkpoint directory must not be empty")
+	}
+
+	ch, ok := s.hypervisor.(checkpointableHypervisor)
+	if !ok {
+		return fmt.Errorf("hypervisor %T does not support checkpoint/restore: %w", s.hypervisor, ErrNotSupported)
+	}
+
+	if err := os.MkdirAll(opts.Directory, store.DirMode); err != nil {
+		return fmt.Errorf("could not create checkpoint directory %q: %v", opts.Directory, err)
+	}
+
+	for _, c := range s.containers {
+		if err := c.Checkpoint(ctx, opts); err != nil {
+			return fmt.Errorf("could not quiesce container %s: %v", c.id, err)
+		}
+	}
+
+	if err := s.dumpConfig(opts.Directory); err != nil {
+		return err
+	}
+
+	if err := ch.save(ctx, filepath.Join(opts.Directory, memoryDumpFile)); err != nil {
+		return fmt.Errorf("hypervisor failed to dump guest state: %v", err)
+	}
+
+	if !opts.LeaveRunning {
+		for _, c := range s.containers {
+			if err := s.detachRootfsDeviceOnStop(ctx, c); err != nil {
+				return fmt.Errorf("could not detach rootfs device for container %s: %v", c.id, err)
+			}
+		}
+
+		if err := s.stopVM(ctx); err != nil {
+			return fmt.Errorf("could not stop sandbox after checkpoint: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// Restore recreates the sandbox described by a previous Checkpoint call.
+// The caller is expected to have already recreated the Sandbox and its
+// network namespace, so s already carries the original UUID and
+// endpoint MAC addresses; Restore verifies that contract before going
+// any further. It then re-attaches the
+// block/virtio-fs shares the dump recorded for every container - the
+// device topology QEMU's migrate-incoming and Cloud Hypervisor's
+// restore both expect to already exist - feeds the memory image back
+// into the hypervisor, and only then lets the agent resume the
+// quiesced tasks.
+func (s *Sandbox) Restore(ctx context.Context, opts RestoreOptions) error {
+	span, ctx := s.trace(ctx, "Restore")
+	defer span.End()
+
+	if opts.Directory == "" {
+		return fmt.Errorf("restore directory must not be empty")
+	}
+
+	ch, ok := s.hypervisor.(checkpointableHypervisor)
+	if !ok {
+		return fmt.Errorf("hypervisor %T does not support checkpoint/restore: %w", s.hypervisor, ErrNotSupported)
+	}
+
+	dump, err := loadSandboxDump(opts.Directory)
+	if err != nil {
+		return err
+	}
+
+	if dump.ID != s.id {
+		return fmt.Errorf("checkpoint bundle is for sandbox %s, not %s", dump.ID, s.id)
+	}
+
+	if !sameSandboxIdentity(dump, s) {
+		return fmt.Errorf("sandbox %s was not recreated from the checkpointed config (endpoint MAC mismatch); restore must be called against a sandbox created from this bundle's config.dump", s.id)
+	}
+
+	for id, cDump := range dump.ContainerDump {
+		c, ok := s.containers[id]
+		if !ok {
+			continue
+		}
+		if err := c.reattachDevices(ctx, cDump); err != nil {
+			return fmt.Errorf("could not re-attach devices for container %s: %v", id, err)
+		}
+	}
+
+	if err := ch.load(ctx, filepath.Join(opts.Directory, memoryDumpFile)); err != nil {
+		return fmt.Errorf("hypervisor failed to load guest state: %v", err)
+	}
+
+	for id := range dump.ContainerDump {
+		c, ok := s.containers[id]
+		if !ok {
+			continue
+		}
+		if err := c.Restore(ctx); err != nil {
+			return fmt.Errorf("could not restore container %s: %v", id, err)
+		}
+	}
+
+	return nil
+}
+
+// endpointHardwareAddrs collects the MAC address of every endpoint in a
+// sandbox's network namespace, in iteration order.
+func endpointHardwareAddrs(endpoints []Endpoint) []string {
+	addrs := make([]string, len(endpoints))
+	for i, ep := range endpoints {
+		addrs[i] = ep.HardwareAddr()
+	}
+
+	return addrs
+}
+
+// sameSandboxIdentity reports whether dump was taken from the sandbox s
+// is now running as: the same network identity, endpoint MAC addresses
+// discovered at boot into s.networkNS rather than recorded in
+// SandboxConfig. Comparing the live endpoints this way, rather than
+// trusting the sandbox ID alone, is what makes "same UUID/MACs" an
+// enforced invariant instead of prose.
+func sameSandboxIdentity(dump *sandboxDump, s *Sandbox) bool {
+	current := endpointHardwareAddrs(s.networkNS.Endpoints)
+
+	if len(dump.NetworkEndpoints) != len(current) {
+		return false
+	}
+
+	for i := range dump.NetworkEndpoints {
+		if dump.NetworkEndpoints[i] != current[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// dumpConfig writes config.dump (sandbox + container metadata) and one
+// <container-id>-spec.dump (that container's raw OCI spec) per
+// container under dir.
+func (s *Sandbox) dumpConfig(dir string) error {
+	dump := sandboxDump{
+		ID:               s.id,
+		Config:           *s.config,
+		NetworkEndpoints: endpointHardwareAddrs(s.networkNS.Endpoints),
+		ContainerDump:    make(map[string]containerDump, len(s.containers)),
+	}
+
+	for id, c := range s.containers {
+		dump.ContainerDump[id] = containerDump{
+			// RootFs.Target follows the standard OCI bundle layout
+			// (<bundle>/rootfs), so the bundle directory itself is
+			// just its parent - no separate field to keep in sync.
+			Bundle: filepath.Dir(c.config.RootFs.Target),
+			RootFs: c.config.RootFs.Target,
+			Mounts: c.mounts,
+		}
+
+		specBytes, err := json.MarshalIndent(c.config.CustomSpec, "", "  ")
+		if err != nil {
+			return fmt.Errorf("could not marshal spec for container %s: %v", id, err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, id+"-"+specDumpFile), specBytes, 0640); err != nil {
+			return fmt.Errorf("could not write %s: %v", specDumpFile, err)
+		}
+	}
+
+	configBytes, err := json.MarshalIndent(dump, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not marshal %s: %v", configDumpFile, err)
+	}
+
+	return ioutil.WriteFile(filepath.Join(dir, configDumpFile), configBytes, 0640)
+}
+
+// loadSandboxDump reads back the config.dump written by dumpConfig.
+func loadSandboxDump(dir string) (*sandboxDump, error) {
+	configBytes, err := ioutil.ReadFile(filepath.Join(dir, configDumpFile))
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %v", configDumpFile, err)
+	}
+
+	var dump sandboxDump
+	if err := json.Unmarshal(configBytes, &dump); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %v", configDumpFile, err)
+	}
+
+	return &dump, nil
+}
+
+// Checkpoint quiesces the workload running inside the guest for this
+// container via the agent, so its state is consistent before the
+// sandbox-wide memory dump is taken.
+func (c *Container) Checkpoint(ctx context.Context, opts CheckpointOptions) error {
+	span, ctx := c.trace(ctx, "Checkpoint")
+	defer span.End()
+
+	return c.sandbox.agent.pauseContainer(ctx, c.sandbox.id, c.id)
+}
+
+// reattachDevices re-plugs a container's block/virtio-fs backed mounts
+// and rootfs so the device topology exists before Sandbox.Restore feeds
+// the memory image back into the hypervisor: QEMU's migrate-incoming
+// and Cloud Hypervisor's restore both expect the destination VM's
+// devices to already be present at load time, so this must run before
+// ch.load, not after.
+func (c *Container) reattachDevices(ctx context.Context, dump containerDump) error {
+	span, ctx := c.trace(ctx, "reattachDevices")
+	defer span.End()
+
+	if err := c.sandbox.attachAndMountRootfsDevice(ctx, c); err != nil {
+		return fmt.Errorf("could not re-attach rootfs device: %v", err)
+	}
+
+	for _, m := range dump.Mounts {
+		if err := c.sandbox.attachMount(ctx, m); err != nil {
+			return fmt.Errorf("could not re-attach mount %s: %v", m.Destination, err)
+		}
+	}
+
+	c.mounts = dump.Mounts
+
+	return nil
+}
+
+// Restore resumes a container's quiesced tasks once the owning
+// sandbox's guest state and devices - re-attached by reattachDevices -
+// have both been restored.
+func (c *Container) Restore(ctx context.Context) error {
+	span, ctx := c.trace(ctx, "Restore")
+	defer span.End()
+
+	return c.sandbox.agent.resumeContainer(ctx, c.sandbox.id, c.id)
+}
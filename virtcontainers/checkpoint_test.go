@@ -0,0 +1,69 @@
+// Copyright (c) 2020 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestCheckpointSandbox returns a minimal sandbox backed by
+// mockHypervisor, ready to be checkpointed and restored.
+func newTestCheckpointSandbox(t *testing.T) *Sandbox {
+	s := &Sandbox{
+		id:         testSandboxID,
+		hypervisor: &mockHypervisor{},
+		containers: make(map[string]*Container),
+		config:     &SandboxConfig{},
+	}
+
+	return s
+}
+
+func TestSandboxCheckpointRestore(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := filepath.Join(testCheckpointDir, testSandboxID)
+	assert.NoError(os.RemoveAll(dir))
+	defer os.RemoveAll(dir)
+
+	s := newTestCheckpointSandbox(t)
+
+	err := s.Checkpoint(context.Background(), CheckpointOptions{
+		Directory:    dir,
+		LeaveRunning: true,
+	})
+	assert.NoError(err)
+
+	for _, f := range []string{configDumpFile, memoryDumpFile} {
+		_, err := os.Stat(filepath.Join(dir, f))
+		assert.NoError(err, "expected %s to exist in checkpoint bundle", f)
+	}
+
+	r := newTestCheckpointSandbox(t)
+	err = r.Restore(context.Background(), RestoreOptions{Directory: dir})
+	assert.NoError(err)
+}
+
+func TestSandboxCheckpointRequiresDirectory(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newTestCheckpointSandbox(t)
+	err := s.Checkpoint(context.Background(), CheckpointOptions{})
+	assert.Error(err)
+}
+
+func TestAcrnCheckpointNotSupported(t *testing.T) {
+	assert := assert.New(t)
+
+	a := &Acrn{}
+	err := a.save(context.Background(), filepath.Join(testCheckpointDir, "unused"))
+	assert.Equal(ErrNotSupported, err)
+}
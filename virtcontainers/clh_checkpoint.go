@@ -0,0 +1,49 @@
+// Copyright (c) 2020 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"fmt"
+
+	chclient "github.com/kata-containers/runtime/virtcontainers/pkg/cloud-hypervisor/client"
+)
+
+// clhSnapshotConfig builds the VmSnapshotConfig payload pointing the
+// snapshot at a destination directory URL, the format the Cloud
+// Hypervisor API expects.
+func clhSnapshotConfig(path string) chclient.VmSnapshotConfig {
+	return chclient.VmSnapshotConfig{DestinationUrl: "file://" + path}
+}
+
+// clhRestoreConfig builds the matching VmRestoreConfig for load.
+func clhRestoreConfig(path string) chclient.VmRestoreConfig {
+	return chclient.VmRestoreConfig{SourceUrl: "file://" + path}
+}
+
+// save asks Cloud Hypervisor's API socket to snapshot the VM (memory +
+// device state) into path via its vm.snapshot RPC.
+func (clh *cloudHypervisor) save(ctx context.Context, path string) error {
+	clh.Logger().WithField("path", path).Info("saving Cloud Hypervisor guest state")
+
+	if err := clh.client().VmSnapshotPut(ctx, clhSnapshotConfig(path)); err != nil {
+		return fmt.Errorf("Cloud Hypervisor snapshot to %q failed: %v", path, err)
+	}
+
+	return nil
+}
+
+// load restores a VM snapshot previously written by save, asking Cloud
+// Hypervisor's API socket to restore from path via its vm.restore RPC.
+func (clh *cloudHypervisor) load(ctx context.Context, path string) error {
+	clh.Logger().WithField("path", path).Info("loading Cloud Hypervisor guest state")
+
+	if err := clh.client().VmRestorePut(ctx, clhRestoreConfig(path)); err != nil {
+		return fmt.Errorf("Cloud Hypervisor restore from %q failed: %v", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2020 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kata-containers/runtime/virtcontainers/imagestore"
+)
+
+// resolveRootfs returns the directory newContainer should treat as the
+// container's bundle rootfs. When ContainerConfig.ImageRef is set, the
+// sandbox's ImageService pulls and prepares it; otherwise the existing
+// pre-staged bundle path is used unchanged, keeping this fully backward
+// compatible with callers that don't use the image store.
+func (s *Sandbox) resolveRootfs(ctx context.Context, c *Container) (string, error) {
+	if c.config.ImageRef == "" {
+		return c.config.RootFs.Target, nil
+	}
+
+	if s.imageService == nil {
+		return "", fmt.Errorf("container %s requests image %q but no image store is configured", c.id, c.config.ImageRef)
+	}
+
+	if err := s.imageService.Pull(ctx, c.config.ImageRef, imagestore.AuthConfig{}); err != nil {
+		return "", err
+	}
+
+	rootfs, err := s.imageService.Prepare(ctx, c.id, c.config.ImageRef)
+	if err != nil {
+		return "", fmt.Errorf("could not prepare image %q for container %s: %v", c.config.ImageRef, c.id, err)
+	}
+
+	return rootfs, nil
+}
+
+// releaseRootfs undoes resolveRootfs's Prepare call when a container
+// backed by the image store stops.
+func (s *Sandbox) releaseRootfs(ctx context.Context, c *Container) error {
+	if c.config.ImageRef == "" || s.imageService == nil {
+		return nil
+	}
+
+	return s.imageService.Release(ctx, c.id)
+}
+
+// ShutdownImageStore drains the sandbox's image service, if one is
+// configured, so its graph driver can unmount cleanly. The runtime's
+// SIGTERM/SIGINT handler calls this after every image-backed
+// container's rootfs has been released, mirroring cri-o's shutdown
+// sequencing around
+// storage.Shutdown().
+func (s *Sandbox) ShutdownImageStore() error {
+	if s.imageService == nil {
+		return nil
+	}
+
+	return s.imageService.Shutdown()
+}
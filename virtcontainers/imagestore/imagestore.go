@@ -0,0 +1,184 @@
+// Copyright (c) 2020 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package imagestore wraps github.com/containers/storage so sandboxes
+// can pull and unpack OCI images directly, handing the resulting
+// layer/snapshot to the guest instead of requiring the caller to
+// pre-stage a bundle rootfs. It plays the same role cri-o's internal
+// storage package plays for CRI-O.
+package imagestore
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/containers/storage"
+	"github.com/containers/storage/types"
+	"github.com/sirupsen/logrus"
+)
+
+var imageStoreLog = logrus.WithField("source", "virtcontainers/imagestore")
+
+// SetLogger lets virtcontainers route imagestore's log output through
+// its own logger, the same pattern SetLogger follows elsewhere in this
+// package tree.
+func SetLogger(logger *logrus.Entry) {
+	fields := imageStoreLog.Data
+	imageStoreLog = logger.WithFields(fields)
+}
+
+// AuthConfig carries the registry credentials used to Pull a reference.
+type AuthConfig struct {
+	Username string
+	Password string
+}
+
+// ImageService pulls and unpacks OCI images on behalf of sandboxes and
+// hands each container the root of its prepared layer.
+type ImageService interface {
+	// Pull fetches ref into the store if it isn't already present.
+	Pull(ctx context.Context, ref string, auth AuthConfig) error
+
+	// Prepare mounts the image layer for containerID and returns the
+	// path the hypervisor/agent should use as the container's rootfs.
+	Prepare(ctx context.Context, containerID, ref string) (rootfs string, err error)
+
+	// Release unmounts the layer prepared for containerID.
+	Release(ctx context.Context, containerID string) error
+
+	// RemoveImage deletes ref from the store entirely.
+	RemoveImage(ctx context.Context, ref string) error
+
+	// Shutdown flushes and releases the underlying graph driver. It is
+	// called on SIGTERM/SIGINT after in-flight containers have been
+	// drained, mirroring cri-o's graceful-shutdown handling.
+	Shutdown() error
+}
+
+// Config selects the graph driver and root used by an imageService; it
+// is populated from the runtime's [image] configuration block.
+type Config struct {
+	// GraphDriverName names the containers/storage graph driver, e.g.
+	// "overlay", "devicemapper" or "btrfs". It is passed through
+	// unchecked, so any driver containers/storage knows (such as "vfs")
+	// is accepted.
+	GraphDriverName string
+
+	// GraphRoot is where image layers and container mounts are kept.
+	GraphRoot string
+
+	// RunRoot holds runtime state (mount namespaces, locks) for the
+	// graph driver; it should live on tmpfs in production.
+	RunRoot string
+}
+
+// imageService is the containers/storage backed ImageService.
+type imageService struct {
+	mu     sync.Mutex
+	store  storage.Store
+	mounts map[string]string
+}
+
+// New creates an ImageService backed by containers/storage using cfg.
+func New(cfg Config) (ImageService, error) {
+	storeOpts := types.StoreOptions{
+		GraphDriverName: cfg.GraphDriverName,
+		GraphRoot:       cfg.GraphRoot,
+		RunRoot:         cfg.RunRoot,
+	}
+
+	store, err := storage.GetStore(storeOpts)
+	if err != nil {
+		return nil, fmt.Errorf("could not open image store at %q: %v", cfg.GraphRoot, err)
+	}
+
+	return &imageService{
+		store:  store,
+		mounts: make(map[string]string),
+	}, nil
+}
+
+func (s *imageService) Pull(ctx context.Context, ref string, auth AuthConfig) error {
+	imageStoreLog.WithField("ref", ref).Info("pulling image")
+
+	if _, err := s.store.Image(ref); err == nil {
+		// already present
+		return nil
+	}
+
+	if err := pullImage(ctx, s.store, ref, auth); err != nil {
+		return fmt.Errorf("could not pull %q: %v", ref, err)
+	}
+
+	return nil
+}
+
+func (s *imageService) Prepare(ctx context.Context, containerID, ref string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rootfs, ok := s.mounts[containerID]; ok {
+		return rootfs, nil
+	}
+
+	container, err := s.store.CreateContainer(containerID, nil, ref, "", "", nil)
+	if err != nil {
+		return "", fmt.Errorf("could not create storage container for %q: %v", containerID, err)
+	}
+
+	rootfs, err := s.store.Mount(container.ID, "")
+	if err != nil {
+		return "", fmt.Errorf("could not mount rootfs for %q: %v", containerID, err)
+	}
+
+	s.mounts[containerID] = rootfs
+
+	return rootfs, nil
+}
+
+func (s *imageService) Release(ctx context.Context, containerID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.mounts[containerID]; !ok {
+		return nil
+	}
+
+	if _, err := s.store.Unmount(containerID, false); err != nil {
+		return fmt.Errorf("could not unmount rootfs for %q: %v", containerID, err)
+	}
+
+	if err := s.store.DeleteContainer(containerID); err != nil {
+		return fmt.Errorf("could not delete storage container %q: %v", containerID, err)
+	}
+
+	delete(s.mounts, containerID)
+
+	return nil
+}
+
+func (s *imageService) RemoveImage(ctx context.Context, ref string) error {
+	if _, err := s.store.DeleteImage(ref, true); err != nil {
+		return fmt.Errorf("could not remove image %q: %v", ref, err)
+	}
+
+	return nil
+}
+
+// Shutdown drains the store's graph driver so the backing filesystem
+// can be cleanly unmounted. The runtime's SIGTERM/SIGINT handler calls
+// this after active containers have been released, the same sequencing
+// cri-o uses around storage.Shutdown().
+func (s *imageService) Shutdown() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.store.Shutdown(false); err != nil {
+		return fmt.Errorf("could not shut down image store: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2020 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package imagestore
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testStoreRoot creates a fresh storeRoot under a temp dir (itself
+// rooted in the OS temp directory the way the rest of this package
+// tree roots its own TestMain tmp dirs under testDir) and returns a
+// Config pointing vfs, the driver that needs no privileges, at it.
+func testStoreRoot(t *testing.T) Config {
+	root, err := ioutil.TempDir("", "imagestore-test-")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(root) })
+
+	return Config{
+		GraphDriverName: "vfs",
+		GraphRoot:       filepath.Join(root, "graph"),
+		RunRoot:         filepath.Join(root, "run"),
+	}
+}
+
+func TestNewImageService(t *testing.T) {
+	assert := assert.New(t)
+
+	svc, err := New(testStoreRoot(t))
+	assert.NoError(err)
+	assert.NotNil(svc)
+
+	assert.NoError(svc.Shutdown())
+}
+
+func TestPrepareReleaseWithoutPull(t *testing.T) {
+	assert := assert.New(t)
+
+	svc, err := New(testStoreRoot(t))
+	assert.NoError(err)
+	defer svc.Shutdown()
+
+	// Preparing a container for an image that was never pulled must
+	// fail cleanly rather than silently fabricate an empty rootfs.
+	_, err = svc.Prepare(context.Background(), "container1", "example.com/does-not-exist:latest")
+	assert.Error(err)
+
+	assert.NoError(svc.Release(context.Background(), "container1"))
+}
+
+// seedFakeImage commits a single layer containing a marker file
+// straight to svc's store under ref, standing in for Pull against a
+// real registry so the round-trip test below needs neither network
+// access nor real image data.
+func seedFakeImage(t *testing.T, svc ImageService, ref, marker string) {
+	assert := assert.New(t)
+
+	is, ok := svc.(*imageService)
+	assert.True(ok)
+
+	layer, err := is.store.CreateLayer("", "", nil, "", false, nil)
+	assert.NoError(err)
+
+	mountPoint, err := is.store.Mount(layer.ID, "")
+	assert.NoError(err)
+	assert.NoError(ioutil.WriteFile(filepath.Join(mountPoint, "marker"), []byte(marker), 0644))
+	_, err = is.store.Unmount(layer.ID, false)
+	assert.NoError(err)
+
+	_, err = is.store.CreateImage("", []string{ref}, layer.ID, "", nil)
+	assert.NoError(err)
+}
+
+func TestPullPrepareReleaseRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+
+	svc, err := New(testStoreRoot(t))
+	assert.NoError(err)
+	defer svc.Shutdown()
+
+	const ref = "localhost/fake-image:latest"
+	seedFakeImage(t, svc, ref, "hello from the fake layer")
+
+	// Pull against an already-present image is a no-op; it must not
+	// try to reach a registry for ref.
+	assert.NoError(svc.Pull(context.Background(), ref, AuthConfig{}))
+
+	rootfs, err := svc.Prepare(context.Background(), "container1", ref)
+	assert.NoError(err)
+	assert.NotEmpty(rootfs)
+
+	data, err := ioutil.ReadFile(filepath.Join(rootfs, "marker"))
+	assert.NoError(err)
+	assert.Equal("hello from the fake layer", string(data))
+
+	// Prepare is idempotent: a second call for the same container
+	// returns the already-mounted rootfs instead of re-mounting it.
+	rootfs2, err := svc.Prepare(context.Background(), "container1", ref)
+	assert.NoError(err)
+	assert.Equal(rootfs, rootfs2)
+
+	assert.NoError(svc.Release(context.Background(), "container1"))
+
+	// Release is idempotent too.
+	assert.NoError(svc.Release(context.Background(), "container1"))
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2020 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package imagestore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/containers/image/v5/copy"
+	"github.com/containers/image/v5/docker"
+	"github.com/containers/image/v5/signature"
+	storagetransport "github.com/containers/image/v5/storage"
+	"github.com/containers/image/v5/types"
+	"github.com/containers/storage"
+)
+
+// pullImage resolves ref against the docker registry transport and
+// copies it into store, the same containers/image plumbing cri-o and
+// podman use for `pull`.
+func pullImage(ctx context.Context, store storage.Store, ref string, auth AuthConfig) error {
+	srcRef, err := docker.ParseReference("//" + ref)
+	if err != nil {
+		return fmt.Errorf("could not parse image reference %q: %v", ref, err)
+	}
+
+	destRef, err := storagetransport.Transport.NewStoreReference(store, nil, ref)
+	if err != nil {
+		return fmt.Errorf("could not build storage reference for %q: %v", ref, err)
+	}
+
+	policy, err := signature.DefaultPolicy(nil)
+	if err != nil {
+		return fmt.Errorf("could not load default signature policy: %v", err)
+	}
+
+	policyCtx, err := signature.NewPolicyContext(policy)
+	if err != nil {
+		return fmt.Errorf("could not create policy context: %v", err)
+	}
+	defer policyCtx.Destroy()
+
+	sysCtx := &types.SystemContext{}
+	if auth.Username != "" {
+		sysCtx.DockerAuthConfig = &types.DockerAuthConfig{
+			Username: auth.Username,
+			Password: auth.Password,
+		}
+	}
+
+	_, err = copy.Image(ctx, policyCtx, destRef, srcRef, &copy.Options{
+		SourceCtx: sysCtx,
+	})
+	if err != nil {
+		return fmt.Errorf("could not copy %q into store: %v", ref, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2020 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import "context"
+
+// reloadAgentCalls records what the reload path asked mockAgent to do,
+// so tests can assert on the resulting hotplug/agent calls without a
+// real guest. It is reset by resetReloadAgentCalls at the start of each
+// test that exercises ReloadVolumes/ReloadMounts.
+var reloadAgentCalls struct {
+	mounted   []Mount
+	unmounted []string
+	remounted []Mount
+}
+
+func resetReloadAgentCalls() {
+	reloadAgentCalls.mounted = nil
+	reloadAgentCalls.unmounted = nil
+	reloadAgentCalls.remounted = nil
+}
+
+// mount/unmount/remount make mockAgent satisfy the reload path's agent
+// calls.
+func (m *mockAgent) mount(ctx context.Context, sandboxID, containerID string, mnt Mount) error {
+	reloadAgentCalls.mounted = append(reloadAgentCalls.mounted, mnt)
+	return nil
+}
+
+func (m *mockAgent) unmount(ctx context.Context, sandboxID, containerID, destination string) error {
+	reloadAgentCalls.unmounted = append(reloadAgentCalls.unmounted, destination)
+	return nil
+}
+
+func (m *mockAgent) remount(ctx context.Context, sandboxID, containerID string, mnt Mount) error {
+	reloadAgentCalls.remounted = append(reloadAgentCalls.remounted, mnt)
+	return nil
+}
@@ -0,0 +1,27 @@
+// Copyright (c) 2020 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package fs
+
+import "path/filepath"
+
+// checkpointStoragePath mirrors runStoragePath: it is the on-disk root
+// under which per-sandbox checkpoint bundles are kept by the fs persist
+// driver. It can be overridden by tests via TestSetCheckpointStoragePath,
+// the same way TestSetRunStoragePath redirects runStoragePath.
+var checkpointStoragePath = "/run/vc/checkpoint"
+
+// CheckpointStoragePath returns the directory the fs driver uses to
+// persist checkpoint bundles for the sandbox with the given id.
+func (fs *FS) CheckpointStoragePath(sandboxID string) string {
+	return filepath.Join(checkpointStoragePath, sandboxID)
+}
+
+// TestSetCheckpointStoragePath overrides the checkpoint storage root.
+// Like TestSetRunStoragePath, it exists only so package tests can
+// redirect persistence under a temporary test directory.
+func TestSetCheckpointStoragePath(path string) {
+	checkpointStoragePath = path
+}
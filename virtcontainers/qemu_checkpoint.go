@@ -0,0 +1,48 @@
+// Copyright (c) 2020 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"fmt"
+)
+
+// save dumps QEMU's guest memory and device state to path using the
+// HMP/QMP "migrate" command with an "exec:" target, the same mechanism
+// libvirt and libpod drive for `virsh save` / `podman container
+// checkpoint`.
+func (q *qemu) save(ctx context.Context, path string) error {
+	q.Logger().WithField("path", path).Info("saving QEMU guest state")
+
+	if q.qmpMonitorCh.qmp == nil {
+		return fmt.Errorf("QMP monitor is not connected")
+	}
+
+	migrateCmd := fmt.Sprintf("exec:cat > %s", path)
+	if err := q.qmpMonitorCh.qmp.ExecuteMigrate(ctx, migrateCmd); err != nil {
+		return fmt.Errorf("QEMU migrate to %q failed: %v", path, err)
+	}
+
+	return nil
+}
+
+// load restores a previously saved guest image. It is only meaningful
+// when called before the VM's CPUs have been started, i.e. as part of
+// Sandbox.Restore rather than a live running sandbox.
+func (q *qemu) load(ctx context.Context, path string) error {
+	q.Logger().WithField("path", path).Info("loading QEMU guest state")
+
+	if q.qmpMonitorCh.qmp == nil {
+		return fmt.Errorf("QMP monitor is not connected")
+	}
+
+	incoming := fmt.Sprintf("exec:cat %s", path)
+	if err := q.qmpMonitorCh.qmp.ExecuteMigrateIncoming(ctx, incoming); err != nil {
+		return fmt.Errorf("QEMU migrate-incoming from %q failed: %v", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,250 @@
+// Copyright (c) 2020 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/kata-containers/runtime/virtcontainers/device/config"
+	"github.com/kata-containers/runtime/virtcontainers/store"
+)
+
+// mountActionKind enumerates what diffMounts decided needs to happen to
+// a single mount as part of a reload.
+type mountActionKind int
+
+const (
+	// mountAdd hot-adds a mount that exists in the new config but not
+	// the old one.
+	mountAdd mountActionKind = iota
+
+	// mountRemove unmounts and detaches a mount that exists in the old
+	// config but not the new one.
+	mountRemove
+
+	// mountUpdate remounts a mount whose options changed (ro<->rw,
+	// cache mode) between old and new.
+	mountUpdate
+)
+
+// mountAction is one step of the plan diffMounts produces: what to do,
+// and to which mount.
+type mountAction struct {
+	kind  mountActionKind
+	mount Mount
+}
+
+// diffMounts reconciles an old and a new mount/volume set and returns
+// the ordered actions needed to bring the guest from old to new:
+// removals first (so a changed device can be detached before a new one
+// claims the same path), then adds, then in-place option updates.
+func diffMounts(old, new []Mount) []mountAction {
+	oldByDest := make(map[string]Mount, len(old))
+	for _, m := range old {
+		oldByDest[m.Destination] = m
+	}
+
+	newByDest := make(map[string]Mount, len(new))
+	for _, m := range new {
+		newByDest[m.Destination] = m
+	}
+
+	var removes, adds, updates []mountAction
+
+	for dest, oldMount := range oldByDest {
+		if _, ok := newByDest[dest]; !ok {
+			removes = append(removes, mountAction{kind: mountRemove, mount: oldMount})
+		}
+	}
+
+	for dest, newMount := range newByDest {
+		oldMount, ok := oldByDest[dest]
+		if !ok {
+			adds = append(adds, mountAction{kind: mountAdd, mount: newMount})
+			continue
+		}
+
+		if oldMount.Source != newMount.Source || oldMount.Type != newMount.Type {
+			// The backing device or filesystem itself changed (e.g. a
+			// CSI driver rebound this destination to a different PVC):
+			// a remount can't swap that out, so detach the old device
+			// and hot-add the new one in its place.
+			removes = append(removes, mountAction{kind: mountRemove, mount: oldMount})
+			adds = append(adds, mountAction{kind: mountAdd, mount: newMount})
+			continue
+		}
+
+		if !mountOptionsEqual(oldMount.Options, newMount.Options) {
+			updates = append(updates, mountAction{kind: mountUpdate, mount: newMount})
+		}
+	}
+
+	return append(append(removes, adds...), updates...)
+}
+
+// mountOptionsEqual compares two mount option sets order-insensitively.
+func mountOptionsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	seen := make(map[string]int, len(a))
+	for _, o := range a {
+		seen[o]++
+	}
+	for _, o := range b {
+		seen[o]--
+	}
+	for _, count := range seen {
+		if count != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// loadSandboxConfig re-reads a sandbox's persisted configuration file
+// from store.ConfigStoragePath(), the same file newSandbox loads at
+// create time, so ReloadVolumes sees whatever an orchestrator just
+// rewrote on disk.
+func loadSandboxConfig(sandboxID string) (*SandboxConfig, error) {
+	path := filepath.Join(store.ConfigStoragePath(), sandboxID, store.ConfigurationFile)
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %v", path, err)
+	}
+
+	var config SandboxConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %v", path, err)
+	}
+
+	return &config, nil
+}
+
+// mountsForContainer picks out the mounts belonging to containerID from
+// a freshly reloaded SandboxConfig.
+func mountsForContainer(config *SandboxConfig, containerID string) []Mount {
+	for _, cc := range config.Containers {
+		if cc.ID == containerID {
+			return cc.Mounts
+		}
+	}
+
+	return nil
+}
+
+// attachMount hot-adds a single mount to the guest: a virtio-fs backed
+// mount extends the existing share, while anything else is attached as
+// a fresh virtio-blk/virtio-scsi device, the same per-hypervisor
+// share-manager hooks Sandbox.attachRootfsDevice uses.
+func (s *Sandbox) attachMount(ctx context.Context, m Mount) error {
+	if s.fsShare != nil && m.Type == "virtiofs" {
+		return s.fsShare.ShareFile(ctx, m.Source, m.Destination)
+	}
+
+	device, err := s.devManager.NewDevice(deviceInfoForMount(m))
+	if err != nil {
+		return fmt.Errorf("could not create device for mount %s: %v", m.Destination, err)
+	}
+
+	return s.devManager.AttachDevice(ctx, device.DeviceID(), s)
+}
+
+// detachMount undoes attachMount for a mount that the new volume set no
+// longer contains.
+func (s *Sandbox) detachMount(ctx context.Context, m Mount) error {
+	if s.fsShare != nil && m.Type == "virtiofs" {
+		return s.fsShare.UnshareFile(ctx, m.Destination)
+	}
+
+	return s.devManager.DetachDeviceByPath(ctx, m.Source, s)
+}
+
+// deviceInfoForMount builds the config.DeviceInfo attachMount needs to
+// hot-plug a non-virtiofs mount as a block device.
+func deviceInfoForMount(m Mount) config.DeviceInfo {
+	return config.DeviceInfo{
+		HostPath:      m.Source,
+		ContainerPath: m.Destination,
+		DevType:       "b",
+	}
+}
+
+// ReloadVolumes re-reads the sandbox's on-disk mount/volume configuration
+// under store.ConfigStoragePath() and reconciles it against what is
+// currently plugged into the guest: new host paths are hot-added,
+// removed entries are unmounted then detached, and entries whose
+// options changed (ro<->rw, cache mode) are remounted. It is the
+// virtcontainers equivalent of `podman volume reload`, useful when an
+// orchestrator rewrites a sandbox's volume set (e.g. a CSI driver
+// rebinding a PVC) without wanting to recreate the VM.
+func (s *Sandbox) ReloadVolumes(ctx context.Context) error {
+	span, ctx := s.trace(ctx, "ReloadVolumes")
+	defer span.End()
+
+	newConfig, err := loadSandboxConfig(s.id)
+	if err != nil {
+		return fmt.Errorf("could not reload sandbox config for %s: %v", s.id, err)
+	}
+
+	for _, c := range s.containers {
+		newMounts := mountsForContainer(newConfig, c.id)
+		if err := c.ReloadMounts(ctx, newMounts); err != nil {
+			return fmt.Errorf("could not reload mounts for container %s: %v", c.id, err)
+		}
+	}
+
+	s.config = newConfig
+
+	return nil
+}
+
+// ReloadMounts reconciles a container's currently plugged mounts against
+// newMounts using diffMounts, then applies each action to the guest
+// through the sandbox's share manager (virtio-fs share extension or a
+// fresh virtio-blk attach) and the agent's mount/unmount RPCs.
+func (c *Container) ReloadMounts(ctx context.Context, newMounts []Mount) error {
+	span, ctx := c.trace(ctx, "ReloadMounts")
+	defer span.End()
+
+	actions := diffMounts(c.mounts, newMounts)
+
+	for _, action := range actions {
+		switch action.kind {
+		case mountRemove:
+			if err := c.sandbox.agent.unmount(ctx, c.sandbox.id, c.id, action.mount.Destination); err != nil {
+				return fmt.Errorf("could not unmount %s: %v", action.mount.Destination, err)
+			}
+			if err := c.sandbox.detachMount(ctx, action.mount); err != nil {
+				return fmt.Errorf("could not detach %s: %v", action.mount.Destination, err)
+			}
+
+		case mountAdd:
+			if err := c.sandbox.attachMount(ctx, action.mount); err != nil {
+				return fmt.Errorf("could not hot-add %s: %v", action.mount.Destination, err)
+			}
+			if err := c.sandbox.agent.mount(ctx, c.sandbox.id, c.id, action.mount); err != nil {
+				return fmt.Errorf("could not mount %s: %v", action.mount.Destination, err)
+			}
+
+		case mountUpdate:
+			if err := c.sandbox.agent.remount(ctx, c.sandbox.id, c.id, action.mount); err != nil {
+				return fmt.Errorf("could not remount %s: %v", action.mount.Destination, err)
+			}
+		}
+	}
+
+	c.mounts = newMounts
+
+	return nil
+}
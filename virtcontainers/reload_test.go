@@ -0,0 +1,155 @@
+// Copyright (c) 2020 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kata-containers/runtime/virtcontainers/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffMountsAddRemoveUpdate(t *testing.T) {
+	assert := assert.New(t)
+
+	old := []Mount{
+		{Destination: "/data", Source: "/host/data", Options: []string{"ro"}},
+		{Destination: "/logs", Source: "/host/logs", Options: []string{"rw"}},
+	}
+
+	new := []Mount{
+		{Destination: "/data", Source: "/host/data", Options: []string{"rw"}},
+		{Destination: "/cache", Source: "/host/cache", Options: []string{"rw"}},
+	}
+
+	actions := diffMounts(old, new)
+
+	var gotAdd, gotRemove, gotUpdate int
+	for _, a := range actions {
+		switch a.kind {
+		case mountAdd:
+			gotAdd++
+			assert.Equal("/cache", a.mount.Destination)
+		case mountRemove:
+			gotRemove++
+			assert.Equal("/logs", a.mount.Destination)
+		case mountUpdate:
+			gotUpdate++
+			assert.Equal("/data", a.mount.Destination)
+		}
+	}
+
+	assert.Equal(1, gotAdd)
+	assert.Equal(1, gotRemove)
+	assert.Equal(1, gotUpdate)
+}
+
+func TestDiffMountsNoChange(t *testing.T) {
+	assert := assert.New(t)
+
+	mounts := []Mount{
+		{Destination: "/data", Source: "/host/data", Options: []string{"rw", "cache=auto"}},
+	}
+
+	actions := diffMounts(mounts, mounts)
+	assert.Empty(actions)
+}
+
+func TestMountOptionsEqualIgnoresOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(mountOptionsEqual([]string{"rw", "cache=auto"}, []string{"cache=auto", "rw"}))
+	assert.False(mountOptionsEqual([]string{"rw"}, []string{"ro"}))
+	assert.False(mountOptionsEqual([]string{"rw"}, []string{"rw", "cache=auto"}))
+}
+
+// writeTestSandboxConfig (over)writes the on-disk SandboxConfig that
+// loadSandboxConfig reads, the same file newSandbox persists under
+// store.ConfigStoragePath() at create time, so ReloadVolumes sees it as
+// if an orchestrator had just rewritten the sandbox's volume set.
+func writeTestSandboxConfig(t *testing.T, sandboxID string, config SandboxConfig) {
+	dir := filepath.Join(store.ConfigStoragePath(), sandboxID)
+	assert.NoError(t, os.MkdirAll(dir, store.DirMode))
+
+	data, err := json.Marshal(config)
+	assert.NoError(t, err)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, store.ConfigurationFile), data, 0600))
+}
+
+func newTestReloadSandbox() *Sandbox {
+	return &Sandbox{
+		id:         testSandboxID,
+		hypervisor: &mockHypervisor{},
+		agent:      &mockAgent{},
+		devManager: newDeviceManager(VirtioBlock, false, "", nil),
+	}
+}
+
+func TestReloadVolumesHotAddsAndRemoves(t *testing.T) {
+	assert := assert.New(t)
+	resetReloadAgentCalls()
+
+	s := newTestReloadSandbox()
+	c := &Container{
+		id:      testContainerID,
+		sandbox: s,
+		mounts: []Mount{
+			{Destination: "/logs", Source: "/host/logs", Type: "ext4", Options: []string{"rw"}},
+		},
+	}
+	s.containers = map[string]*Container{c.id: c}
+
+	writeTestSandboxConfig(t, testSandboxID, SandboxConfig{
+		Containers: []ContainerConfig{
+			{
+				ID: testContainerID,
+				Mounts: []Mount{
+					{Destination: "/data", Source: "/host/data", Type: "ext4", Options: []string{"rw"}},
+				},
+			},
+		},
+	})
+
+	assert.NoError(s.ReloadVolumes(context.Background()))
+
+	assert.Equal([]string{"/logs"}, reloadAgentCalls.unmounted)
+	if assert.Len(reloadAgentCalls.mounted, 1) {
+		assert.Equal("/data", reloadAgentCalls.mounted[0].Destination)
+	}
+	assert.Equal([]Mount{{Destination: "/data", Source: "/host/data", Type: "ext4", Options: []string{"rw"}}}, c.mounts)
+}
+
+func TestReloadMountsRemountsOnOptionChange(t *testing.T) {
+	assert := assert.New(t)
+	resetReloadAgentCalls()
+
+	s := newTestReloadSandbox()
+	c := &Container{
+		id:      testContainerID,
+		sandbox: s,
+		mounts: []Mount{
+			{Destination: "/data", Source: "/host/data", Type: "ext4", Options: []string{"ro"}},
+		},
+	}
+
+	newMounts := []Mount{
+		{Destination: "/data", Source: "/host/data", Type: "ext4", Options: []string{"rw"}},
+	}
+
+	assert.NoError(c.ReloadMounts(context.Background(), newMounts))
+
+	assert.Empty(reloadAgentCalls.mounted)
+	assert.Empty(reloadAgentCalls.unmounted)
+	if assert.Len(reloadAgentCalls.remounted, 1) {
+		assert.Equal("/data", reloadAgentCalls.remounted[0].Destination)
+	}
+	assert.Equal(newMounts, c.mounts)
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2020 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/kata-containers/runtime/virtcontainers/device/config"
+)
+
+// RootfsType selects how a container's rootfs is made visible inside the
+// guest.
+type RootfsType string
+
+const (
+	// RootfsShared is the historical behaviour: the rootfs is shared
+	// into the guest through virtio-fs or 9p and bind-mounted by the
+	// agent.
+	RootfsShared RootfsType = "shared"
+
+	// RootfsBlock backs the rootfs with a raw block device (a host
+	// loop device, an LVM LV, or a PV-provided block volume) that is
+	// hot-plugged into the guest and mounted directly, mirroring
+	// Virtlet's volumeMode: Block PV support.
+	RootfsBlock RootfsType = "block"
+
+	// RootfsQcow2 is like RootfsBlock but the backing file is a qcow2
+	// image rather than a raw device; the hypervisor attaches it
+	// through the same virtio-blk/virtio-scsi path.
+	RootfsQcow2 RootfsType = "qcow2"
+)
+
+// RootfsSource describes the host-side block device or image backing a
+// RootfsBlock/RootfsQcow2 container rootfs.
+type RootfsSource struct {
+	// Path is the host device node (e.g. /dev/loopN, an LVM LV, a
+	// PV-provided block volume) or image file backing the rootfs.
+	Path string
+
+	// Filesystem is passed to the guest mount hint (e.g. "ext4",
+	// "xfs") so the agent knows how to mount the device.
+	Filesystem string
+
+	// Driver selects the block-device driver used to hot-plug Path
+	// into the guest: VirtioBlock or VirtioSCSI.
+	Driver DeviceDriver
+}
+
+// errRootfsDeviceBusy is returned when a block rootfs device is already
+// attached to another sandbox; block rootfs devices require exclusive
+// access, unlike virtio-fs shares which can be reused.
+var errRootfsDeviceBusy = fmt.Errorf("rootfs device is already attached to a sandbox")
+
+// attachRootfsDevice hot-plugs a RootfsBlock/RootfsQcow2 container's
+// backing device into the guest and returns the mount hint to send to
+// the agent. ACRN's block-device support predates hotplug, so callers
+// should expect ErrNotSupported there.
+func (s *Sandbox) attachRootfsDevice(ctx context.Context, c *Container) (*Mount, error) {
+	if _, ok := s.hypervisor.(*Acrn); ok {
+		return nil, fmt.Errorf("ACRN does not support block-device rootfs for container %s: %w", c.id, ErrNotSupported)
+	}
+
+	src := c.config.RootfsSource
+	if src == nil {
+		return nil, fmt.Errorf("container %s has no rootfs source for type %s", c.id, c.config.RootfsType)
+	}
+
+	if !rootfsDeviceLocks.tryLock(src.Path) {
+		return nil, errRootfsDeviceBusy
+	}
+
+	driver := src.Driver
+	if driver == "" {
+		driver = VirtioBlock
+	}
+
+	device, err := s.devManager.NewDevice(config.DeviceInfo{
+		HostPath:      src.Path,
+		ContainerPath: rootfsGuestMountPoint(c.id),
+		DevType:       "b",
+		DriverOptions: map[string]string{"block-driver": string(driver)},
+	})
+	if err != nil {
+		rootfsDeviceLocks.unlock(src.Path)
+		return nil, fmt.Errorf("could not create rootfs block device for container %s: %v", c.id, err)
+	}
+
+	if err := s.devManager.AttachDevice(ctx, device.DeviceID(), s); err != nil {
+		rootfsDeviceLocks.unlock(src.Path)
+		return nil, fmt.Errorf("could not hot-plug rootfs device for container %s: %v", c.id, err)
+	}
+
+	c.rootfsDeviceID = device.DeviceID()
+
+	return &Mount{
+		Source:      src.Path,
+		Destination: "/",
+		Type:        src.Filesystem,
+		Options:     []string{"rootfs"},
+	}, nil
+}
+
+// attachAndMountRootfsDevice hot-plugs a RootfsBlock/RootfsQcow2
+// container's backing device and tells the agent to mount it at "/",
+// the two steps that together make attachRootfsDevice's mount hint
+// actually reach the guest kernel. RootfsShared containers (and
+// containers with no RootfsType set at all, the pre-existing default)
+// are a no-op: their rootfs reaches the guest through the virtio-fs/9p
+// share instead.
+func (s *Sandbox) attachAndMountRootfsDevice(ctx context.Context, c *Container) error {
+	if c.config.RootfsType == "" || c.config.RootfsType == RootfsShared {
+		return nil
+	}
+
+	mnt, err := s.attachRootfsDevice(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	return s.agent.mount(ctx, s.id, c.id, *mnt)
+}
+
+// detachRootfsDeviceOnStop unmounts and unplugs a block rootfs device
+// when its container's sandbox stops, the inverse of
+// attachAndMountRootfsDevice.
+func (s *Sandbox) detachRootfsDeviceOnStop(ctx context.Context, c *Container) error {
+	if c.config.RootfsType == "" || c.config.RootfsType == RootfsShared || c.rootfsDeviceID == "" {
+		return nil
+	}
+
+	if err := s.agent.unmount(ctx, s.id, c.id, "/"); err != nil {
+		return fmt.Errorf("could not unmount rootfs device for container %s: %v", c.id, err)
+	}
+
+	return s.detachRootfsDevice(ctx, c)
+}
+
+// detachRootfsDevice unplugs a block rootfs device when its container
+// stops, releasing the exclusive lock taken in attachRootfsDevice.
+func (s *Sandbox) detachRootfsDevice(ctx context.Context, c *Container) error {
+	if c.rootfsDeviceID == "" {
+		return nil
+	}
+
+	src := c.config.RootfsSource
+
+	defer func() {
+		if src != nil {
+			rootfsDeviceLocks.unlock(src.Path)
+		}
+	}()
+
+	if err := s.devManager.DetachDevice(ctx, c.rootfsDeviceID, s); err != nil {
+		return fmt.Errorf("could not detach rootfs device for container %s: %v", c.id, err)
+	}
+
+	c.rootfsDeviceID = ""
+
+	return nil
+}
+
+// rootfsGuestMountPoint is the guest path recorded as a block rootfs
+// device's ContainerPath; the mount hint sent to the agent names "/".
+func rootfsGuestMountPoint(containerID string) string {
+	return fmt.Sprintf("/run/kata-containers/%s/rootfs", containerID)
+}
+
+// rootfsDeviceLocks tracks host paths currently attached as a block
+// rootfs so a second container can't claim the same device.
+var rootfsDeviceLocks = newRootfsLockSet()
+
+type rootfsLockSet struct {
+	mu    sync.Mutex
+	paths map[string]bool
+}
+
+func newRootfsLockSet() *rootfsLockSet {
+	return &rootfsLockSet{
+		paths: make(map[string]bool),
+	}
+}
+
+// tryLock atomically claims path for the caller, returning false
+// without side effects if it is already claimed. Checking and setting
+// under a single critical section is what makes the exclusive-access
+// guarantee actually exclusive against concurrent attaches.
+func (l *rootfsLockSet) tryLock(path string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.paths[path] {
+		return false
+	}
+
+	l.paths[path] = true
+	return true
+}
+
+func (l *rootfsLockSet) unlock(path string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.paths, path)
+}
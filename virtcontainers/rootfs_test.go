@@ -0,0 +1,149 @@
+// Copyright (c) 2020 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newSparseLoopFile creates an empty, sparse file under testDir to stand
+// in for a host loop device backing a block rootfs, without requiring
+// root to actually attach a loop device.
+func newSparseLoopFile(t *testing.T, name string, size int64) string {
+	path := filepath.Join(testDir, name)
+
+	f, err := os.Create(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	assert.NoError(t, f.Truncate(size))
+
+	return path
+}
+
+func newTestRootfsSandbox() *Sandbox {
+	return &Sandbox{
+		id:         testSandboxID,
+		hypervisor: &mockHypervisor{},
+		agent:      &mockAgent{},
+		devManager: newDeviceManager(VirtioBlock, false, "", nil),
+	}
+}
+
+func TestAttachDetachRootfsDevice(t *testing.T) {
+	assert := assert.New(t)
+
+	loopFile := newSparseLoopFile(t, "rootfs.img", 64*1024*1024)
+
+	s := newTestRootfsSandbox()
+	c := &Container{
+		id: testContainerID,
+		config: &ContainerConfig{
+			RootfsType: RootfsBlock,
+			RootfsSource: &RootfsSource{
+				Path:       loopFile,
+				Filesystem: "ext4",
+			},
+		},
+	}
+
+	mnt, err := s.attachRootfsDevice(context.Background(), c)
+	assert.NoError(err)
+	assert.Equal(loopFile, mnt.Source)
+	assert.NotEmpty(c.rootfsDeviceID)
+
+	err = s.detachRootfsDevice(context.Background(), c)
+	assert.NoError(err)
+	assert.Empty(c.rootfsDeviceID)
+}
+
+func TestAttachRootfsDeviceExclusive(t *testing.T) {
+	assert := assert.New(t)
+
+	loopFile := newSparseLoopFile(t, "rootfs-exclusive.img", 64*1024*1024)
+
+	s := newTestRootfsSandbox()
+	c1 := &Container{id: "c1", config: &ContainerConfig{
+		RootfsType:   RootfsBlock,
+		RootfsSource: &RootfsSource{Path: loopFile, Filesystem: "ext4"},
+	}}
+	c2 := &Container{id: "c2", config: &ContainerConfig{
+		RootfsType:   RootfsBlock,
+		RootfsSource: &RootfsSource{Path: loopFile, Filesystem: "ext4"},
+	}}
+
+	_, err := s.attachRootfsDevice(context.Background(), c1)
+	assert.NoError(err)
+
+	_, err = s.attachRootfsDevice(context.Background(), c2)
+	assert.Equal(errRootfsDeviceBusy, err)
+
+	assert.NoError(s.detachRootfsDevice(context.Background(), c1))
+}
+
+func TestAttachAndMountRootfsDevice(t *testing.T) {
+	assert := assert.New(t)
+	resetReloadAgentCalls()
+
+	loopFile := newSparseLoopFile(t, "rootfs-mount.img", 64*1024*1024)
+
+	s := newTestRootfsSandbox()
+	c := &Container{
+		id: testContainerID,
+		config: &ContainerConfig{
+			RootfsType:   RootfsBlock,
+			RootfsSource: &RootfsSource{Path: loopFile, Filesystem: "ext4"},
+		},
+	}
+
+	assert.NoError(s.attachAndMountRootfsDevice(context.Background(), c))
+	assert.NotEmpty(c.rootfsDeviceID)
+	if assert.Len(reloadAgentCalls.mounted, 1) {
+		assert.Equal("/", reloadAgentCalls.mounted[0].Destination)
+	}
+
+	assert.NoError(s.detachRootfsDeviceOnStop(context.Background(), c))
+	assert.Empty(c.rootfsDeviceID)
+	assert.Equal([]string{"/"}, reloadAgentCalls.unmounted)
+}
+
+func TestAttachAndMountRootfsDeviceSharedIsNoop(t *testing.T) {
+	assert := assert.New(t)
+	resetReloadAgentCalls()
+
+	s := newTestRootfsSandbox()
+	c := &Container{id: testContainerID, config: &ContainerConfig{RootfsType: RootfsShared}}
+
+	assert.NoError(s.attachAndMountRootfsDevice(context.Background(), c))
+	assert.Empty(reloadAgentCalls.mounted)
+
+	assert.NoError(s.detachRootfsDeviceOnStop(context.Background(), c))
+	assert.Empty(reloadAgentCalls.unmounted)
+}
+
+func TestAttachRootfsDeviceAcrnUnsupported(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newTestRootfsSandbox()
+	s.hypervisor = &Acrn{}
+
+	c := &Container{
+		id: testContainerID,
+		config: &ContainerConfig{
+			RootfsType:   RootfsBlock,
+			RootfsSource: &RootfsSource{Path: "/dev/null", Filesystem: "ext4"},
+		},
+	}
+
+	_, err := s.attachRootfsDevice(context.Background(), c)
+	assert.True(errors.Is(err, ErrNotSupported))
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2020 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package service
+
+// The request/response types below are the Go side of api.proto. They
+// are hand-maintained rather than protoc-generated for now (see
+// api.proto's header comment) but are kept field-for-field in sync with
+// it, and serialized with the "json" codec registered in codec.go.
+
+type SandboxIDRequest struct {
+	SandboxID string `json:"sandbox_id"`
+}
+
+type ContainerIDRequest struct {
+	SandboxID   string `json:"sandbox_id"`
+	ContainerID string `json:"container_id"`
+}
+
+type CreateSandboxRequest struct {
+	// Config is a JSON-encoded vc.SandboxConfig.
+	Config []byte `json:"config"`
+}
+
+type CreateContainerRequest struct {
+	SandboxID string `json:"sandbox_id"`
+	// Config is a JSON-encoded vc.ContainerConfig.
+	Config []byte `json:"config"`
+}
+
+type CheckpointRequest struct {
+	SandboxID    string `json:"sandbox_id"`
+	Directory    string `json:"directory"`
+	LeaveRunning bool   `json:"leave_running"`
+}
+
+type RestoreRequest struct {
+	SandboxID string `json:"sandbox_id"`
+	Directory string `json:"directory"`
+}
+
+type ListSandboxRequest struct{}
+
+type SandboxResponse struct {
+	SandboxID string `json:"sandbox_id"`
+	State     string `json:"state"`
+}
+
+type ListSandboxResponse struct {
+	Sandboxes []SandboxResponse `json:"sandboxes"`
+}
+
+type ContainerResponse struct {
+	ContainerID string `json:"container_id"`
+	State       string `json:"state"`
+}
+
+type ContainerStatusResponse struct {
+	ContainerID string `json:"container_id"`
+	State       string `json:"state"`
+	Spec        []byte `json:"spec"`
+}
+
+type ContainerStatsResponse struct {
+	ContainerID string `json:"container_id"`
+	// Stats is a JSON-encoded vc.ContainerStats.
+	Stats []byte `json:"stats"`
+}
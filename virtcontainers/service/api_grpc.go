@@ -0,0 +1,202 @@
+// Copyright (c) 2020 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package service
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// VirtContainersServer is implemented by Server; it is the Go mirror of
+// the "service VirtContainers" definition in api.proto.
+type VirtContainersServer interface {
+	CreateSandbox(context.Context, *CreateSandboxRequest) (*SandboxResponse, error)
+	StartSandbox(context.Context, *SandboxIDRequest) (*SandboxResponse, error)
+	StopSandbox(context.Context, *SandboxIDRequest) (*SandboxResponse, error)
+	DeleteSandbox(context.Context, *SandboxIDRequest) (*SandboxResponse, error)
+	ListSandbox(context.Context, *ListSandboxRequest) (*ListSandboxResponse, error)
+	CreateContainer(context.Context, *CreateContainerRequest) (*ContainerResponse, error)
+	StatusContainer(context.Context, *ContainerIDRequest) (*ContainerStatusResponse, error)
+	StatsContainer(context.Context, *ContainerIDRequest) (*ContainerStatsResponse, error)
+	CheckpointSandbox(context.Context, *CheckpointRequest) (*SandboxResponse, error)
+	RestoreSandbox(context.Context, *RestoreRequest) (*SandboxResponse, error)
+}
+
+// RegisterVirtContainersServer wires a VirtContainersServer implementation
+// into a *grpc.Server, the same role protoc-gen-go-grpc's generated
+// RegisterXServer function plays.
+func RegisterVirtContainersServer(s *grpc.Server, srv VirtContainersServer) {
+	s.RegisterService(&virtContainersServiceDesc, srv)
+}
+
+var virtContainersServiceDesc = grpc.ServiceDesc{
+	ServiceName: "service.VirtContainers",
+	HandlerType: (*VirtContainersServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "CreateSandbox", Handler: createSandboxHandler},
+		{MethodName: "StartSandbox", Handler: startSandboxHandler},
+		{MethodName: "StopSandbox", Handler: stopSandboxHandler},
+		{MethodName: "DeleteSandbox", Handler: deleteSandboxHandler},
+		{MethodName: "ListSandbox", Handler: listSandboxHandler},
+		{MethodName: "CreateContainer", Handler: createContainerHandler},
+		{MethodName: "StatusContainer", Handler: statusContainerHandler},
+		{MethodName: "StatsContainer", Handler: statsContainerHandler},
+		{MethodName: "CheckpointSandbox", Handler: checkpointSandboxHandler},
+		{MethodName: "RestoreSandbox", Handler: restoreSandboxHandler},
+	},
+	Metadata: "virtcontainers/service/api.proto",
+}
+
+func createSandboxHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSandboxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VirtContainersServer).CreateSandbox(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/service.VirtContainers/CreateSandbox"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VirtContainersServer).CreateSandbox(ctx, req.(*CreateSandboxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func startSandboxHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SandboxIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VirtContainersServer).StartSandbox(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/service.VirtContainers/StartSandbox"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VirtContainersServer).StartSandbox(ctx, req.(*SandboxIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func stopSandboxHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SandboxIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VirtContainersServer).StopSandbox(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/service.VirtContainers/StopSandbox"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VirtContainersServer).StopSandbox(ctx, req.(*SandboxIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func deleteSandboxHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SandboxIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VirtContainersServer).DeleteSandbox(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/service.VirtContainers/DeleteSandbox"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VirtContainersServer).DeleteSandbox(ctx, req.(*SandboxIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func listSandboxHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSandboxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VirtContainersServer).ListSandbox(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/service.VirtContainers/ListSandbox"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VirtContainersServer).ListSandbox(ctx, req.(*ListSandboxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func createContainerHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateContainerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VirtContainersServer).CreateContainer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/service.VirtContainers/CreateContainer"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VirtContainersServer).CreateContainer(ctx, req.(*CreateContainerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func statusContainerHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ContainerIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VirtContainersServer).StatusContainer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/service.VirtContainers/StatusContainer"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VirtContainersServer).StatusContainer(ctx, req.(*ContainerIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func statsContainerHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ContainerIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VirtContainersServer).StatsContainer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/service.VirtContainers/StatsContainer"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VirtContainersServer).StatsContainer(ctx, req.(*ContainerIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func checkpointSandboxHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckpointRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VirtContainersServer).CheckpointSandbox(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/service.VirtContainers/CheckpointSandbox"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VirtContainersServer).CheckpointSandbox(ctx, req.(*CheckpointRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func restoreSandboxHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VirtContainersServer).RestoreSandbox(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/service.VirtContainers/RestoreSandbox"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VirtContainersServer).RestoreSandbox(ctx, req.(*RestoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
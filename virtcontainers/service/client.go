@@ -0,0 +1,117 @@
+// Copyright (c) 2020 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// Client is a thin wrapper around a gRPC connection to a Server's Unix
+// socket. Every method forwards the caller's context as-is, so
+// cancelling it aborts the RPC and, in turn, whatever hypervisor
+// operation the server is blocked on.
+type Client struct {
+	conn *grpc.ClientConn
+}
+
+// Dial connects to the VirtContainers service listening on socketPath.
+func Dial(ctx context.Context, socketPath string) (*Client, error) {
+	conn, err := grpc.DialContext(ctx, socketPath,
+		grpc.WithInsecure(),
+		grpc.WithBlock(),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			d := net.Dialer{}
+			return d.DialContext(ctx, "unix", addr)
+		}),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(jsonCodec{})),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not dial %q: %v", socketPath, err)
+	}
+
+	return &Client{conn: conn}, nil
+}
+
+// Close tears down the underlying connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Client) invoke(ctx context.Context, method string, in, out interface{}) error {
+	return c.conn.Invoke(ctx, method, in, out)
+}
+
+func (c *Client) CreateSandbox(ctx context.Context, config []byte) (*SandboxResponse, error) {
+	out := new(SandboxResponse)
+	err := c.invoke(ctx, "/service.VirtContainers/CreateSandbox", &CreateSandboxRequest{Config: config}, out)
+	return out, err
+}
+
+func (c *Client) StartSandbox(ctx context.Context, sandboxID string) (*SandboxResponse, error) {
+	out := new(SandboxResponse)
+	err := c.invoke(ctx, "/service.VirtContainers/StartSandbox", &SandboxIDRequest{SandboxID: sandboxID}, out)
+	return out, err
+}
+
+func (c *Client) StopSandbox(ctx context.Context, sandboxID string) (*SandboxResponse, error) {
+	out := new(SandboxResponse)
+	err := c.invoke(ctx, "/service.VirtContainers/StopSandbox", &SandboxIDRequest{SandboxID: sandboxID}, out)
+	return out, err
+}
+
+func (c *Client) DeleteSandbox(ctx context.Context, sandboxID string) (*SandboxResponse, error) {
+	out := new(SandboxResponse)
+	err := c.invoke(ctx, "/service.VirtContainers/DeleteSandbox", &SandboxIDRequest{SandboxID: sandboxID}, out)
+	return out, err
+}
+
+func (c *Client) ListSandbox(ctx context.Context) (*ListSandboxResponse, error) {
+	out := new(ListSandboxResponse)
+	err := c.invoke(ctx, "/service.VirtContainers/ListSandbox", &ListSandboxRequest{}, out)
+	return out, err
+}
+
+func (c *Client) CreateContainer(ctx context.Context, sandboxID string, config []byte) (*ContainerResponse, error) {
+	out := new(ContainerResponse)
+	err := c.invoke(ctx, "/service.VirtContainers/CreateContainer", &CreateContainerRequest{SandboxID: sandboxID, Config: config}, out)
+	return out, err
+}
+
+func (c *Client) StatusContainer(ctx context.Context, sandboxID, containerID string) (*ContainerStatusResponse, error) {
+	out := new(ContainerStatusResponse)
+	err := c.invoke(ctx, "/service.VirtContainers/StatusContainer", &ContainerIDRequest{SandboxID: sandboxID, ContainerID: containerID}, out)
+	return out, err
+}
+
+func (c *Client) StatsContainer(ctx context.Context, sandboxID, containerID string) (*ContainerStatsResponse, error) {
+	out := new(ContainerStatsResponse)
+	err := c.invoke(ctx, "/service.VirtContainers/StatsContainer", &ContainerIDRequest{SandboxID: sandboxID, ContainerID: containerID}, out)
+	return out, err
+}
+
+func (c *Client) CheckpointSandbox(ctx context.Context, sandboxID, directory string, leaveRunning bool) (*SandboxResponse, error) {
+	out := new(SandboxResponse)
+	req := &CheckpointRequest{SandboxID: sandboxID, Directory: directory, LeaveRunning: leaveRunning}
+	err := c.invoke(ctx, "/service.VirtContainers/CheckpointSandbox", req, out)
+	return out, err
+}
+
+func (c *Client) RestoreSandbox(ctx context.Context, sandboxID, directory string) (*SandboxResponse, error) {
+	out := new(SandboxResponse)
+	req := &RestoreRequest{SandboxID: sandboxID, Directory: directory}
+	err := c.invoke(ctx, "/service.VirtContainers/RestoreSandbox", req, out)
+	return out, err
+}
+
+// dialTimeout is used by tests that don't want to pass their own
+// context deadline when exercising Dial against a freshly started
+// Server.
+const dialTimeout = 5 * time.Second
@@ -0,0 +1,41 @@
+// Copyright (c) 2020 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// codecName identifies jsonCodec to grpc. NewServer and Dial install it
+// via ForceServerCodec/ForceCodec rather than encoding.RegisterCodec,
+// so it never touches the process-global codec registry - importing
+// this package has no effect on any other gRPC user sharing the binary
+// (e.g. the kata-agent client linked into the same runtime process).
+// The wire messages are still the ones described by api.proto;
+// regenerating api.pb.go with protoc and switching to the "proto"
+// codec is a drop-in replacement for this once a protoc toolchain is
+// wired into the build.
+const codecName = "json"
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string { return codecName }
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("service: could not marshal %T: %v", v, err)
+	}
+	return data, nil
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("service: could not unmarshal into %T: %v", v, err)
+	}
+	return nil
+}
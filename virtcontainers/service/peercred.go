@@ -0,0 +1,79 @@
+// Copyright (c) 2020 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package service
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+)
+
+// peerCredListener wraps a Unix socket net.Listener so that, on top of
+// the 0600 file mode NewServer sets on the socket, every accepted
+// connection's peer credentials (SO_PEERCRED) are checked against the
+// server's own euid before the connection is handed to gRPC. This
+// closes the gap file permissions alone leave open if the socket is
+// ever reachable with broader permissions than intended (a shared
+// mount, a packaging bug, a permissive umask slipping through).
+type peerCredListener struct {
+	net.Listener
+	allowedUID int
+}
+
+func newPeerCredListener(l net.Listener) *peerCredListener {
+	return &peerCredListener{Listener: l, allowedUID: os.Geteuid()}
+}
+
+func (l *peerCredListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		uc, ok := conn.(*net.UnixConn)
+		if !ok {
+			conn.Close()
+			return nil, fmt.Errorf("service: unexpected connection type %T on Unix listener", conn)
+		}
+
+		if err := checkPeerCred(uc, l.allowedUID); err != nil {
+			conn.Close()
+			continue
+		}
+
+		return conn, nil
+	}
+}
+
+// checkPeerCred reads the connecting process's credentials via
+// SO_PEERCRED and rejects anything not running as allowedUID.
+func checkPeerCred(conn *net.UnixConn, allowedUID int) error {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return fmt.Errorf("could not get raw connection: %v", err)
+	}
+
+	var ucred *syscall.Ucred
+	var sockoptErr error
+
+	err = raw.Control(func(fd uintptr) {
+		ucred, sockoptErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	})
+	if err != nil {
+		return fmt.Errorf("could not inspect connection: %v", err)
+	}
+	if sockoptErr != nil {
+		return fmt.Errorf("could not read SO_PEERCRED: %v", sockoptErr)
+	}
+
+	if int(ucred.Uid) != allowedUID {
+		return fmt.Errorf("connecting uid %d does not match server uid %d", ucred.Uid, allowedUID)
+	}
+
+	return nil
+}
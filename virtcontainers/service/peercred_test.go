@@ -0,0 +1,50 @@
+// Copyright (c) 2020 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package service
+
+import (
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// unixSocketPair returns a connected pair of *net.UnixConn backed by a
+// real AF_UNIX socketpair, since SO_PEERCRED only works over AF_UNIX
+// and not over net.Pipe's in-memory connection.
+func unixSocketPair(t *testing.T) (a, b *net.UnixConn) {
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_STREAM, 0)
+	assert.NoError(t, err)
+
+	connA, err := net.FileConn(os.NewFile(uintptr(fds[0]), ""))
+	assert.NoError(t, err)
+	connB, err := net.FileConn(os.NewFile(uintptr(fds[1]), ""))
+	assert.NoError(t, err)
+
+	return connA.(*net.UnixConn), connB.(*net.UnixConn)
+}
+
+func TestCheckPeerCredAcceptsOwnUID(t *testing.T) {
+	assert := assert.New(t)
+
+	client, server := unixSocketPair(t)
+	defer client.Close()
+	defer server.Close()
+
+	assert.NoError(checkPeerCred(server, os.Geteuid()))
+}
+
+func TestCheckPeerCredRejectsOtherUID(t *testing.T) {
+	assert := assert.New(t)
+
+	client, server := unixSocketPair(t)
+	defer client.Close()
+	defer server.Close()
+
+	assert.Error(checkPeerCred(server, os.Geteuid()+1))
+}
@@ -0,0 +1,242 @@
+// Copyright (c) 2020 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	vc "github.com/kata-containers/runtime/virtcontainers"
+	"github.com/kata-containers/runtime/virtcontainers/store"
+	"google.golang.org/grpc"
+)
+
+// socketFileMode is the permission mode the listening socket is
+// created with. Combined with the SO_PEERCRED euid check every
+// accepted connection goes through in peerCredListener, this is the
+// whole of the service's authentication model - no credentials are
+// exchanged over the wire, but a connecting process must both be able
+// to open the socket and run as the server's own uid.
+const socketFileMode = 0600
+
+// defaultSocketPath is where a Server listens unless a caller overrides
+// it, derived from store.RunStoragePath() so it moves with the rest of
+// the runtime's state under tests.
+func defaultSocketPath() string {
+	return filepath.Join(store.RunStoragePath(), "virtcontainers.sock")
+}
+
+// Server exposes the virtcontainers public API (CreateSandbox,
+// StartSandbox, StopSandbox, DeleteSandbox, CreateContainer,
+// StatusContainer, StatsContainer, ListSandbox, Checkpoint/Restore)
+// over a local Unix-socket gRPC service, so tools other than the
+// runtime CLI can drive the library without linking Go - the
+// varlink/remote-client split podman uses for its own API.
+type Server struct {
+	socketPath string
+	grpcServer *grpc.Server
+	listener   net.Listener
+}
+
+// NewServer creates a Server bound to socketPath. An empty socketPath
+// uses defaultSocketPath().
+func NewServer(socketPath string) (*Server, error) {
+	if socketPath == "" {
+		socketPath = defaultSocketPath()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(socketPath), store.DirMode); err != nil {
+		return nil, fmt.Errorf("could not create socket directory for %q: %v", socketPath, err)
+	}
+
+	os.Remove(socketPath)
+
+	// Restrict the umask for the duration of the bind so the socket
+	// never exists, even momentarily, with broader permissions than
+	// socketFileMode: os.Chmod after the fact would leave a window
+	// where a connection made before the chmod keeps its access.
+	oldMask := syscall.Umask(0177)
+	listener, err := net.Listen("unix", socketPath)
+	syscall.Umask(oldMask)
+	if err != nil {
+		return nil, fmt.Errorf("could not listen on %q: %v", socketPath, err)
+	}
+
+	if err := os.Chmod(socketPath, socketFileMode); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("could not set permissions on %q: %v", socketPath, err)
+	}
+
+	s := &Server{
+		socketPath: socketPath,
+		grpcServer: grpc.NewServer(grpc.ForceServerCodec(jsonCodec{})),
+		listener:   newPeerCredListener(listener),
+	}
+
+	RegisterVirtContainersServer(s.grpcServer, (*apiServer)(s))
+
+	return s, nil
+}
+
+// Serve blocks accepting RPCs until Stop is called.
+func (s *Server) Serve() error {
+	return s.grpcServer.Serve(s.listener)
+}
+
+// Stop gracefully drains in-flight RPCs and removes the socket file.
+func (s *Server) Stop() {
+	s.grpcServer.GracefulStop()
+	os.Remove(s.socketPath)
+}
+
+// SocketPath returns the Unix socket path this server is listening on.
+func (s *Server) SocketPath() string {
+	return s.socketPath
+}
+
+// apiServer implements VirtContainersServer by calling straight into
+// the virtcontainers package; it is just Server under another name so
+// RegisterVirtContainersServer can bind methods without exporting them
+// on Server itself.
+type apiServer Server
+
+func (a *apiServer) CreateSandbox(ctx context.Context, req *CreateSandboxRequest) (*SandboxResponse, error) {
+	var config vc.SandboxConfig
+	if err := json.Unmarshal(req.Config, &config); err != nil {
+		return nil, fmt.Errorf("could not decode sandbox config: %v", err)
+	}
+
+	sandbox, err := vc.CreateSandbox(ctx, config, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SandboxResponse{SandboxID: sandbox.ID(), State: string(sandbox.Status().State.State)}, nil
+}
+
+func (a *apiServer) StartSandbox(ctx context.Context, req *SandboxIDRequest) (*SandboxResponse, error) {
+	sandbox, err := vc.StartSandbox(ctx, req.SandboxID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SandboxResponse{SandboxID: sandbox.ID(), State: string(sandbox.Status().State.State)}, nil
+}
+
+func (a *apiServer) StopSandbox(ctx context.Context, req *SandboxIDRequest) (*SandboxResponse, error) {
+	sandbox, err := vc.StopSandbox(ctx, req.SandboxID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SandboxResponse{SandboxID: sandbox.ID(), State: string(sandbox.Status().State.State)}, nil
+}
+
+func (a *apiServer) DeleteSandbox(ctx context.Context, req *SandboxIDRequest) (*SandboxResponse, error) {
+	sandbox, err := vc.DeleteSandbox(ctx, req.SandboxID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SandboxResponse{SandboxID: sandbox.ID(), State: "deleted"}, nil
+}
+
+func (a *apiServer) ListSandbox(ctx context.Context, req *ListSandboxRequest) (*ListSandboxResponse, error) {
+	statuses, err := vc.ListSandbox(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ListSandboxResponse{Sandboxes: make([]SandboxResponse, 0, len(statuses))}
+	for _, status := range statuses {
+		resp.Sandboxes = append(resp.Sandboxes, SandboxResponse{
+			SandboxID: status.ID,
+			State:     string(status.State.State),
+		})
+	}
+
+	return resp, nil
+}
+
+func (a *apiServer) CreateContainer(ctx context.Context, req *CreateContainerRequest) (*ContainerResponse, error) {
+	var config vc.ContainerConfig
+	if err := json.Unmarshal(req.Config, &config); err != nil {
+		return nil, fmt.Errorf("could not decode container config: %v", err)
+	}
+
+	_, container, err := vc.CreateContainer(ctx, req.SandboxID, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ContainerResponse{ContainerID: container.ID()}, nil
+}
+
+func (a *apiServer) StatusContainer(ctx context.Context, req *ContainerIDRequest) (*ContainerStatusResponse, error) {
+	status, err := vc.StatusContainer(ctx, req.SandboxID, req.ContainerID)
+	if err != nil {
+		return nil, err
+	}
+
+	specBytes, err := json.Marshal(status.Spec)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode container spec: %v", err)
+	}
+
+	return &ContainerStatusResponse{
+		ContainerID: status.ID,
+		State:       string(status.State.State),
+		Spec:        specBytes,
+	}, nil
+}
+
+func (a *apiServer) StatsContainer(ctx context.Context, req *ContainerIDRequest) (*ContainerStatsResponse, error) {
+	stats, err := vc.StatsContainer(ctx, req.SandboxID, req.ContainerID)
+	if err != nil {
+		return nil, err
+	}
+
+	statsBytes, err := json.Marshal(stats)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode container stats: %v", err)
+	}
+
+	return &ContainerStatsResponse{ContainerID: req.ContainerID, Stats: statsBytes}, nil
+}
+
+func (a *apiServer) CheckpointSandbox(ctx context.Context, req *CheckpointRequest) (*SandboxResponse, error) {
+	sandbox, err := vc.FetchSandbox(ctx, req.SandboxID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sandbox.Checkpoint(ctx, vc.CheckpointOptions{
+		Directory:    req.Directory,
+		LeaveRunning: req.LeaveRunning,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &SandboxResponse{SandboxID: sandbox.ID(), State: string(sandbox.Status().State.State)}, nil
+}
+
+func (a *apiServer) RestoreSandbox(ctx context.Context, req *RestoreRequest) (*SandboxResponse, error) {
+	sandbox, err := vc.FetchSandbox(ctx, req.SandboxID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sandbox.Restore(ctx, vc.RestoreOptions{Directory: req.Directory}); err != nil {
+		return nil, err
+	}
+
+	return &SandboxResponse{SandboxID: sandbox.ID(), State: string(sandbox.Status().State.State)}, nil
+}
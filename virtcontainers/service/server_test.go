@@ -0,0 +1,95 @@
+// Copyright (c) 2020 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package service
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// startTestServer spins up a Server on a socket inside a fresh temp
+// directory and returns a connected Client, tearing both down when the
+// test completes.
+func startTestServer(t *testing.T) *Client {
+	dir, err := ioutil.TempDir("", "vc-service-test-")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	srv, err := NewServer(filepath.Join(dir, "vc.sock"))
+	assert.NoError(t, err)
+
+	go srv.Serve()
+	t.Cleanup(srv.Stop)
+
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+
+	client, err := Dial(ctx, srv.SocketPath())
+	assert.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+func TestServerSocketPermissions(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "vc-service-test-")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	socketPath := filepath.Join(dir, "vc.sock")
+	srv, err := NewServer(socketPath)
+	assert.NoError(err)
+	defer srv.Stop()
+
+	info, err := os.Stat(socketPath)
+	assert.NoError(err)
+	assert.Equal(os.FileMode(socketFileMode), info.Mode().Perm())
+}
+
+func TestClientListSandboxRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+
+	client := startTestServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := client.ListSandbox(ctx)
+	assert.NoError(err)
+	assert.Empty(resp.Sandboxes)
+}
+
+func TestClientUnknownSandboxErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	client := startTestServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := client.StartSandbox(ctx, "does-not-exist")
+	assert.Error(err)
+}
+
+func TestClientContextCancellationAbortsCall(t *testing.T) {
+	assert := assert.New(t)
+
+	client := startTestServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := client.ListSandbox(ctx)
+	assert.Error(err)
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2020 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	vc "github.com/kata-containers/runtime/virtcontainers"
+	"github.com/kata-containers/runtime/virtcontainers/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestServiceListSandboxEndToEnd drives the gRPC/Varlink-style
+// management API exposed by the service package against the real
+// virtcontainers package, reusing the fake utils.StartCmd hypervisor
+// stub TestMain installs for the rest of this package's tests.
+func TestServiceListSandboxEndToEnd(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "vc-service-e2e-")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	socketPath := filepath.Join(dir, "service-e2e.sock")
+
+	srv, err := service.NewServer(socketPath)
+	assert.NoError(err)
+	defer srv.Stop()
+
+	go srv.Serve()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := service.Dial(ctx, socketPath)
+	assert.NoError(err)
+	defer client.Close()
+
+	resp, err := client.ListSandbox(ctx)
+	assert.NoError(err)
+	assert.Empty(resp.Sandboxes)
+
+	config, err := json.Marshal(vc.SandboxConfig{ID: "service-e2e-sandbox"})
+	assert.NoError(err)
+
+	_, err = client.CreateSandbox(ctx, config)
+	// A minimal config without hypervisor/agent sections is expected to
+	// be rejected by validation; this still proves the RPC round-trips
+	// through the Unix socket and surfaces the library's own error.
+	assert.Error(err)
+}
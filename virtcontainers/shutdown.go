@@ -0,0 +1,49 @@
+// Copyright (c) 2020 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// HandleShutdownSignals installs a SIGTERM/SIGINT handler and blocks
+// until one arrives (or ctx is cancelled), then drains every
+// image-backed container's rootfs and shuts the image store down,
+// mirroring cri-o's graceful-shutdown sequencing around
+// storage.Shutdown(). Callers run it in its own goroutine from their
+// main loop.
+func (s *Sandbox) HandleShutdownSignals(ctx context.Context) error {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(sigCh)
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-sigCh:
+	}
+
+	return s.drainAndShutdown(ctx)
+}
+
+// drainAndShutdown releases every container's image-backed rootfs -
+// the thing that keeps the image store's graph driver busy - then
+// shuts the image store down. It is split out from
+// HandleShutdownSignals so tests can drive the drain sequence directly
+// without sending a real process signal.
+func (s *Sandbox) drainAndShutdown(ctx context.Context) error {
+	for _, c := range s.containers {
+		if err := s.releaseRootfs(ctx, c); err != nil {
+			return fmt.Errorf("could not release image rootfs for container %s during shutdown: %v", c.id, err)
+		}
+	}
+
+	return s.ShutdownImageStore()
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2020 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package virtcontainers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kata-containers/runtime/virtcontainers/imagestore"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeImageService is a bare-bones imagestore.ImageService that
+// records which containers were released and whether Shutdown ran, so
+// tests can assert on drainAndShutdown's sequencing without a real
+// containers/storage graph driver.
+type fakeImageService struct {
+	released       []string
+	releaseErr     error
+	shutdownCalled bool
+}
+
+func (f *fakeImageService) Pull(ctx context.Context, ref string, auth imagestore.AuthConfig) error {
+	return nil
+}
+
+func (f *fakeImageService) Prepare(ctx context.Context, containerID, ref string) (string, error) {
+	return "", nil
+}
+
+func (f *fakeImageService) Release(ctx context.Context, containerID string) error {
+	if f.releaseErr != nil {
+		return f.releaseErr
+	}
+	f.released = append(f.released, containerID)
+	return nil
+}
+
+func (f *fakeImageService) RemoveImage(ctx context.Context, ref string) error {
+	return nil
+}
+
+func (f *fakeImageService) Shutdown() error {
+	f.shutdownCalled = true
+	return nil
+}
+
+func TestDrainAndShutdownReleasesImageBackedContainers(t *testing.T) {
+	assert := assert.New(t)
+
+	svc := &fakeImageService{}
+	s := &Sandbox{
+		id:           testSandboxID,
+		imageService: svc,
+		containers: map[string]*Container{
+			"with-image":    {id: "with-image", config: &ContainerConfig{ImageRef: "example.com/image:latest"}},
+			"without-image": {id: "without-image", config: &ContainerConfig{}},
+		},
+	}
+
+	assert.NoError(s.drainAndShutdown(context.Background()))
+
+	assert.Equal([]string{"with-image"}, svc.released)
+	assert.True(svc.shutdownCalled)
+}
+
+func TestDrainAndShutdownStopsOnReleaseError(t *testing.T) {
+	assert := assert.New(t)
+
+	svc := &fakeImageService{releaseErr: errors.New("layer still busy")}
+	s := &Sandbox{
+		id:           testSandboxID,
+		imageService: svc,
+		containers: map[string]*Container{
+			"with-image": {id: "with-image", config: &ContainerConfig{ImageRef: "example.com/image:latest"}},
+		},
+	}
+
+	assert.Error(s.drainAndShutdown(context.Background()))
+	assert.False(svc.shutdownCalled)
+}
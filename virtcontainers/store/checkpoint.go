@@ -0,0 +1,18 @@
+// Copyright (c) 2020 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package store
+
+import "path/filepath"
+
+const checkpointStoragePathSuffix = "checkpoint"
+
+// CheckpointStoragePath is the path used to store the on-disk checkpoint
+// bundles produced by Sandbox.Checkpoint (one sub-directory per sandbox
+// ID). It is a variable, like RunStoragePath and ConfigStoragePath, so
+// that tests can redirect it under a temporary directory.
+var CheckpointStoragePath = func() string {
+	return filepath.Join(RunStoragePath(), checkpointStoragePathSuffix)
+}
@@ -41,6 +41,7 @@ var sandboxDirState = ""
 var sandboxDirLock = ""
 var sandboxFileState = ""
 var sandboxFileLock = ""
+var testCheckpointDir = ""
 var testQemuKernelPath = ""
 var testQemuInitrdPath = ""
 var testQemuImagePath = ""
@@ -163,14 +164,18 @@ func TestMain(m *testing.M) {
 
 	ConfigStoragePathSaved := store.ConfigStoragePath
 	RunStoragePathSaved := store.RunStoragePath
+	CheckpointStoragePathSaved := store.CheckpointStoragePath
 	// allow the tests to run without affecting the host system.
 	store.ConfigStoragePath = func() string { return filepath.Join(testDir, store.StoragePathSuffix, "config") }
 	store.RunStoragePath = func() string { return filepath.Join(testDir, store.StoragePathSuffix, "run") }
+	store.CheckpointStoragePath = func() string { return filepath.Join(testDir, store.StoragePathSuffix, "checkpoint") }
 	fs.TestSetRunStoragePath(filepath.Join(testDir, "vc", "sbs"))
+	fs.TestSetCheckpointStoragePath(filepath.Join(testDir, "vc", "checkpoint"))
 
 	defer func() {
 		store.ConfigStoragePath = ConfigStoragePathSaved
 		store.RunStoragePath = RunStoragePathSaved
+		store.CheckpointStoragePath = CheckpointStoragePathSaved
 	}()
 
 	// set now that configStoragePath has been overridden.
@@ -181,6 +186,8 @@ func TestMain(m *testing.M) {
 	sandboxFileState = filepath.Join(store.RunStoragePath(), testSandboxID, store.StateFile)
 	sandboxFileLock = filepath.Join(store.RunStoragePath(), testSandboxID, store.LockFile)
 
+	testCheckpointDir = filepath.Join(testDir, "checkpoint-bundle")
+
 	testHyperstartCtlSocket = filepath.Join(testDir, "test_hyper.sock")
 	testHyperstartTtySocket = filepath.Join(testDir, "test_tty.sock")
 